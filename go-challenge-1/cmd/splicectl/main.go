@@ -0,0 +1,103 @@
+// Command splicectl decodes, edits, and re-encodes .splice drum pattern
+// files.
+//
+// Usage:
+//
+//	splicectl decode <path>
+//	splicectl encode <path>
+//	splicectl dump-json <path>
+//	splicectl set-tempo <path> <bpm>
+//	splicectl toggle-step <path> <track-index> <step>
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	drum "github.com/k0nserv/go-challenge-solutions/go-challenge-1"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("usage: splicectl <decode|encode|dump-json|set-tempo|toggle-step> <path> [args...]")
+	}
+
+	command, path := args[0], args[1]
+
+	pattern, err := drum.DecodeFile(path)
+	if err != nil {
+		return fmt.Errorf("decode %s: %w", path, err)
+	}
+
+	switch command {
+	case "decode":
+		fmt.Print(pattern.String())
+		return nil
+
+	case "encode":
+		return drum.EncodeFile(pattern, path)
+
+	case "dump-json":
+		return dumpJSON(pattern)
+
+	case "set-tempo":
+		flagSet := flag.NewFlagSet("set-tempo", flag.ExitOnError)
+		flagSet.Parse(args[2:])
+		if flagSet.NArg() < 1 {
+			return fmt.Errorf("usage: splicectl set-tempo <path> <bpm>")
+		}
+
+		var bpm float32
+		if _, err := fmt.Sscanf(flagSet.Arg(0), "%f", &bpm); err != nil {
+			return fmt.Errorf("invalid bpm %q: %w", flagSet.Arg(0), err)
+		}
+
+		pattern.SetTempo(bpm)
+		return drum.EncodeFile(pattern, path)
+
+	case "toggle-step":
+		flagSet := flag.NewFlagSet("toggle-step", flag.ExitOnError)
+		flagSet.Parse(args[2:])
+		if flagSet.NArg() < 2 {
+			return fmt.Errorf("usage: splicectl toggle-step <path> <track-index> <step>")
+		}
+
+		var trackIdx uint32
+		var step int
+		if _, err := fmt.Sscanf(flagSet.Arg(0), "%d", &trackIdx); err != nil {
+			return fmt.Errorf("invalid track index %q: %w", flagSet.Arg(0), err)
+		}
+		if _, err := fmt.Sscanf(flagSet.Arg(1), "%d", &step); err != nil {
+			return fmt.Errorf("invalid step %q: %w", flagSet.Arg(1), err)
+		}
+
+		on, err := pattern.StepOn(trackIdx, step)
+		if err != nil {
+			return err
+		}
+		if err := pattern.SetStep(trackIdx, step, !on); err != nil {
+			return err
+		}
+		return drum.EncodeFile(pattern, path)
+
+	default:
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+func dumpJSON(pattern *drum.Pattern) error {
+	data, err := pattern.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(append(data, '\n'))
+	return err
+}
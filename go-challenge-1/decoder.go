@@ -11,11 +11,30 @@ import (
 
 var magicNumber = [...]byte{0x53, 0x50, 0x4c, 0x49, 0x43, 0x45}
 
-const corruptFileError = "The file could not be read because it was corrupted"
+// maxTrackCount bounds how many tracks a single pattern may declare, so that
+// a corrupt remaining-byte count can't make Decode loop (and allocate)
+// without end.
+const maxTrackCount = 1024
+
+var (
+	// ErrBadMagic is returned when a stream does not begin with the SPLICE
+	// magic number.
+	ErrBadMagic = errors.New("drum: unknown file format, does not match magic number")
+
+	// ErrTruncated is returned when a stream ends before the declared
+	// remaining-byte count has been consumed, or when a track's declared
+	// size disagrees with the bytes actually available.
+	ErrTruncated = errors.New("drum: file is truncated or corrupted")
+
+	// ErrUnsupportedVersion is returned when the stream ends before the 32
+	// byte version field has been read in full. A version field with no
+	// null byte is accepted and read as all 32 bytes.
+	ErrUnsupportedVersion = errors.New("drum: unsupported or malformed version string")
+)
 
-// DecodeFile decodes the drum machine file found at the provided path
-// and returns a pointer to a parsed pattern which is the entry point to the
-// rest of the data.
+// Decoder reads a Pattern from a SPLICE-formatted stream. Unlike DecodeFile,
+// a Decoder reads from any io.Reader, so callers can decode a pattern held
+// in memory, received over the network, or read from a tar entry.
 //
 // File format:
 // 6 bytes. Magic number SPLICE
@@ -28,93 +47,117 @@ const corruptFileError = "The file could not be read because it was corrupted"
 // 1 Byte length of Track name. e.g 0x04 for Kick
 // n bytes for track name, length from previous byte
 // 16 consecuvite bytes for each step
-func DecodeFile(path string) (*Pattern, error) {
-	file, err := os.Open(path)
-
-	defer func() {
-		err := file.Close()
-		if err != nil {
-			panic(err)
-		}
-	}()
-
-	if err != nil {
-		return nil, err
-	}
+type Decoder struct {
+	r io.Reader
+}
 
-	err = verifyMagicNumber(file)
+// NewDecoder returns a Decoder that reads a SPLICE pattern from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
 
-	if err != nil {
-		return nil, err
+// Decode reads a single pattern from the underlying stream into p.
+func (d *Decoder) Decode(p *Pattern) error {
+	if err := verifyMagicNumber(d.r); err != nil {
+		return err
 	}
 
 	var remainingBytes uint64
-	err = binary.Read(file, binary.BigEndian, &remainingBytes)
-	if err != nil {
-		return nil, errors.New(corruptFileError)
+	if err := binary.Read(d.r, binary.BigEndian, &remainingBytes); err != nil {
+		return ErrTruncated
 	}
 
-	version, err := readVersionString(file, binary.LittleEndian)
+	version, err := readVersionString(d.r, binary.LittleEndian)
 	if err != nil {
-		return nil, errors.New(corruptFileError)
+		return ErrUnsupportedVersion
+	}
+	if remainingBytes < 32 {
+		return ErrTruncated
 	}
 	remainingBytes -= 32
 
 	var tempo float32
-
-	err = binary.Read(file, binary.LittleEndian, &tempo)
-	if err != nil {
-		return nil, errors.New(corruptFileError)
+	if err := binary.Read(d.r, binary.LittleEndian, &tempo); err != nil {
+		return ErrTruncated
+	}
+	if remainingBytes < 4 {
+		return ErrTruncated
 	}
 	remainingBytes -= 4
 
-	var tracks = make([]Track, 0)
-
-	err = nil
+	var tracks []Track
 	for remainingBytes > 0 {
-		track, err := readTrack(file, binary.LittleEndian)
+		if len(tracks) >= maxTrackCount {
+			return fmt.Errorf("drum: pattern declares more than %d tracks: %w", maxTrackCount, ErrTruncated)
+		}
 
-		if err != nil && err != io.EOF {
-			return nil, err
-		} else if err == io.EOF {
-			break
+		track, err := readTrack(d.r, binary.LittleEndian)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return ErrTruncated
+		} else if err != nil {
+			return err
+		}
+
+		if uint64(track.sizeInBytes) > remainingBytes {
+			return ErrTruncated
 		}
 
 		tracks = append(tracks, *track)
 		remainingBytes -= uint64(track.sizeInBytes)
 	}
 
-	p := Pattern{
-		version: *version,
-		tempo:   tempo,
-		tracks:  tracks,
+	p.version = *version
+	p.tempo = tempo
+	p.tracks = tracks
+	return nil
+}
+
+// DecodeFile decodes the drum machine file found at the provided path
+// and returns a pointer to a parsed pattern which is the entry point to the
+// rest of the data. It is a thin wrapper around Decoder for callers that
+// only need to read from a path on disk.
+func DecodeFile(path string) (*Pattern, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		err := file.Close()
+		if err != nil {
+			panic(err)
+		}
+	}()
+
+	var p Pattern
+	if err := NewDecoder(file).Decode(&p); err != nil {
+		return nil, err
 	}
+
 	return &p, nil
 }
 
-// Verify the magic number SPLICE in the file
-func verifyMagicNumber(file *os.File) error {
-	buffer := make([]byte, 6)
-	count, err := file.Read(buffer)
-
-	if count != 6 || err != nil {
-		return err
+// Verify the magic number SPLICE at the start of r.
+func verifyMagicNumber(r io.Reader) error {
+	buffer := make([]byte, len(magicNumber))
+	if _, err := io.ReadFull(r, buffer); err != nil {
+		return ErrBadMagic
 	}
 
 	if bytes.Equal(buffer, magicNumber[:]) {
 		return nil
 	}
 
-	return errors.New("Unknown file format, does not match magic number")
+	return ErrBadMagic
 }
 
-func readVersionString(file *os.File, byteOrder binary.ByteOrder) (*string, error) {
-	return readString(file, byteOrder, 32, true)
+func readVersionString(r io.Reader, byteOrder binary.ByteOrder) (*string, error) {
+	return readString(r, byteOrder, 32, true)
 }
 
-func readString(file *os.File, byteOrder binary.ByteOrder, length uint, nullTerminated bool) (*string, error) {
+func readString(r io.Reader, byteOrder binary.ByteOrder, length uint, nullTerminated bool) (*string, error) {
 	buffer := make([]byte, length)
-	err := binary.Read(file, byteOrder, buffer)
+	err := binary.Read(r, byteOrder, buffer)
 
 	if err != nil {
 		return nil, err
@@ -123,6 +166,9 @@ func readString(file *os.File, byteOrder binary.ByteOrder, length uint, nullTerm
 	var result string
 	if nullTerminated {
 		zeroIndex := bytes.Index(buffer, []byte{0})
+		if zeroIndex == -1 {
+			zeroIndex = len(buffer)
+		}
 		result = string(buffer[:zeroIndex])
 	} else {
 		result = string(buffer[:length])
@@ -136,30 +182,33 @@ func readString(file *os.File, byteOrder binary.ByteOrder, length uint, nullTerm
 // 1 Byte length of Track name. e.g 0x04 for Kick
 // n bytes for track name, length from previous byte
 // 16 consecuvite bytes for each step
-func readTrack(file *os.File, byteOrder binary.ByteOrder) (*Track, error) {
+//
+// nameLength is a single byte, so the track name is already bounded to 255
+// bytes without any extra validation here.
+func readTrack(r io.Reader, byteOrder binary.ByteOrder) (*Track, error) {
 	var sizeInBytes uint
 	var index uint32
-	err := binary.Read(file, byteOrder, &index)
+	err := binary.Read(r, byteOrder, &index)
 	if err != nil {
 		return nil, err
 	}
 	sizeInBytes += 4
 
 	var nameLength uint8
-	err = binary.Read(file, byteOrder, &nameLength)
+	err = binary.Read(r, byteOrder, &nameLength)
 	if err != nil {
 		return nil, err
 	}
 	sizeInBytes++
 
-	name, err := readString(file, byteOrder, uint(nameLength), false)
+	name, err := readString(r, byteOrder, uint(nameLength), false)
 	if err != nil {
 		return nil, err
 	}
 	sizeInBytes += uint(nameLength)
 
 	var steps [16]uint8
-	err = binary.Read(file, byteOrder, &steps)
+	err = binary.Read(r, byteOrder, &steps)
 	if err != nil {
 		return nil, err
 	}
@@ -188,6 +237,21 @@ type Track struct {
 	sizeInBytes uint
 }
 
+// Index returns the track's index.
+func (t *Track) Index() uint32 {
+	return t.index
+}
+
+// Name returns the track's name.
+func (t *Track) Name() string {
+	return t.name
+}
+
+// Steps returns the track's 16 steps.
+func (t *Track) Steps() [16]bool {
+	return t.steps
+}
+
 func (t *Track) String() string {
 	var result string
 	result += fmt.Sprintf("(%d) %s\t", t.index, t.name)
@@ -216,6 +280,16 @@ type Pattern struct {
 	tracks  []Track
 }
 
+// Tempo returns the pattern's tempo, in beats per minute.
+func (p *Pattern) Tempo() float32 {
+	return p.tempo
+}
+
+// Tracks returns the pattern's tracks.
+func (p *Pattern) Tracks() []Track {
+	return p.tracks
+}
+
 func (p *Pattern) String() string {
 	var result string
 	result += fmt.Sprintf("Saved with HW Version: %s\n", p.version)
@@ -0,0 +1,130 @@
+package drum
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// AddTrack appends a track with the given index, name, and steps to the
+// pattern.
+func (p *Pattern) AddTrack(index uint32, name string, steps [16]bool) {
+	p.tracks = append(p.tracks, Track{
+		index: index,
+		name:  name,
+		steps: steps,
+	})
+}
+
+// RemoveTrack removes the track with the given index from the pattern, if
+// one is present.
+func (p *Pattern) RemoveTrack(index uint32) {
+	for i, track := range p.tracks {
+		if track.index == index {
+			p.tracks = append(p.tracks[:i], p.tracks[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetStep turns a single 16th-note step of the track with the given index
+// on or off. It returns an error if no track with that index exists or step
+// is outside 0-15.
+func (p *Pattern) SetStep(trackIdx uint32, step int, on bool) error {
+	if step < 0 || step > 15 {
+		return fmt.Errorf("drum: step %d out of range, must be 0-15", step)
+	}
+
+	for i, track := range p.tracks {
+		if track.index == trackIdx {
+			p.tracks[i].steps[step] = on
+			return nil
+		}
+	}
+
+	return fmt.Errorf("drum: no track with index %d", trackIdx)
+}
+
+// StepOn reports whether the given 16th-note step of the track with the
+// given index is on. It returns an error if no track with that index
+// exists or step is outside 0-15.
+func (p *Pattern) StepOn(trackIdx uint32, step int) (bool, error) {
+	if step < 0 || step > 15 {
+		return false, fmt.Errorf("drum: step %d out of range, must be 0-15", step)
+	}
+
+	for _, track := range p.tracks {
+		if track.index == trackIdx {
+			return track.steps[step], nil
+		}
+	}
+
+	return false, fmt.Errorf("drum: no track with index %d", trackIdx)
+}
+
+// SetTempo sets the pattern's tempo, in beats per minute.
+func (p *Pattern) SetTempo(tempo float32) {
+	p.tempo = tempo
+}
+
+// Clone returns a deep copy of the pattern.
+func (p *Pattern) Clone() *Pattern {
+	clone := *p
+	clone.tracks = make([]Track, len(p.tracks))
+	copy(clone.tracks, p.tracks)
+	return &clone
+}
+
+// Merge appends a copy of every track in other to p. Tempo and version are
+// left unchanged.
+func (p *Pattern) Merge(other *Pattern) {
+	p.tracks = append(p.tracks, other.tracks...)
+}
+
+// trackJSON is the exported shape used to marshal a Track, since its fields
+// are unexported.
+type trackJSON struct {
+	Index uint32   `json:"index"`
+	Name  string   `json:"name"`
+	Steps [16]bool `json:"steps"`
+}
+
+// patternJSON is the exported shape used to marshal a Pattern, since its
+// fields are unexported.
+type patternJSON struct {
+	Version string      `json:"version"`
+	Tempo   float32     `json:"tempo"`
+	Tracks  []trackJSON `json:"tracks"`
+}
+
+// MarshalJSON encodes the pattern as JSON, exposing its version, tempo, and
+// tracks.
+func (p *Pattern) MarshalJSON() ([]byte, error) {
+	tracks := make([]trackJSON, len(p.tracks))
+	for i, t := range p.tracks {
+		tracks[i] = trackJSON{Index: t.index, Name: t.name, Steps: t.steps}
+	}
+
+	return json.Marshal(patternJSON{
+		Version: p.version,
+		Tempo:   p.tempo,
+		Tracks:  tracks,
+	})
+}
+
+// UnmarshalJSON decodes a pattern previously produced by MarshalJSON.
+func (p *Pattern) UnmarshalJSON(data []byte) error {
+	var decoded patternJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	tracks := make([]Track, len(decoded.Tracks))
+	for i, t := range decoded.Tracks {
+		tracks[i] = Track{index: t.Index, name: t.Name, steps: t.Steps}
+	}
+
+	p.version = decoded.Version
+	p.tempo = decoded.Tempo
+	p.tracks = tracks
+	return nil
+}
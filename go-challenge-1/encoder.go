@@ -0,0 +1,98 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// EncodeFile serializes pattern into the SPLICE binary layout described on
+// Decoder and writes the result to the file at path, creating it if it
+// does not already exist and truncating it otherwise.
+func EncodeFile(pattern *Pattern, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		err := file.Close()
+		if err != nil {
+			panic(err)
+		}
+	}()
+
+	return pattern.Encode(file)
+}
+
+// Encode writes the pattern to w using the same binary layout that
+// DecodeFile reads: magic number, big-endian remaining-byte count, 32-byte
+// null-padded version, little-endian float32 tempo, and then each track.
+// Decoding the bytes written by Encode produces a Pattern equal to the one
+// it was given.
+func (p *Pattern) Encode(w io.Writer) error {
+	var body bytes.Buffer
+
+	if len(p.version) > 32 {
+		return fmt.Errorf("drum: version %q is too long to encode", p.version)
+	}
+
+	version := make([]byte, 32)
+	copy(version, p.version)
+	if _, err := body.Write(version); err != nil {
+		return err
+	}
+
+	if err := binary.Write(&body, binary.LittleEndian, p.tempo); err != nil {
+		return err
+	}
+
+	for _, track := range p.tracks {
+		if err := track.encode(&body); err != nil {
+			return err
+		}
+	}
+
+	if _, err := w.Write(magicNumber[:]); err != nil {
+		return err
+	}
+
+	remainingBytes := uint64(body.Len())
+	if err := binary.Write(w, binary.BigEndian, remainingBytes); err != nil {
+		return err
+	}
+
+	_, err := w.Write(body.Bytes())
+	return err
+}
+
+// encode writes the track to w using the 4 byte index, 1 byte name length,
+// name, and 16 step bytes layout that readTrack expects.
+func (t *Track) encode(w io.Writer) error {
+	if err := binary.Write(w, binary.LittleEndian, t.index); err != nil {
+		return err
+	}
+
+	if len(t.name) > 0xff {
+		return fmt.Errorf("drum: track name %q is too long to encode", t.name)
+	}
+
+	if err := binary.Write(w, binary.LittleEndian, uint8(len(t.name))); err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(w, t.name); err != nil {
+		return err
+	}
+
+	var steps [16]uint8
+	for i, on := range t.steps {
+		if on {
+			steps[i] = 1
+		}
+	}
+
+	return binary.Write(w, binary.LittleEndian, &steps)
+}
@@ -0,0 +1,316 @@
+package drum
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// drumChannel is the General MIDI percussion channel (channel 10, zero
+// indexed).
+const drumChannel = 9
+
+// ticksPerQuarterNote is the SMF division used for files written by
+// WriteSMF. With 16th-note steps this keeps every step an exact number of
+// ticks (ticksPerQuarterNote/4).
+const ticksPerQuarterNote = 96
+
+// DefaultGMDrumNotes maps well-known track names to their General MIDI drum
+// note, matched case-insensitively by WriteSMF and ReadSMF. Callers with a
+// non-GM drum kit can pass their own table to WriteSMFWithNotes/
+// ReadSMFWithNotes instead.
+var DefaultGMDrumNotes = map[string]uint8{
+	"kick":     36,
+	"snare":    38,
+	"hh-close": 42,
+	"hh-open":  46,
+	"low tom":  41,
+	"mid tom":  45,
+	"hi tom":   48,
+	"clap":     39,
+	"cowbell":  56,
+}
+
+// ErrUnsupportedSMF is returned by ReadSMF when the stream is not a format
+// 0 or 1 Standard MIDI File.
+var ErrUnsupportedSMF = errors.New("drum: unsupported or malformed Standard MIDI File")
+
+// WriteSMF converts the pattern to a format 0 Standard MIDI File and writes
+// it to w, mapping track names to notes via DefaultGMDrumNotes. See
+// WriteSMFWithNotes to use a different name→note table.
+func (p *Pattern) WriteSMF(w io.Writer) error {
+	return p.WriteSMFWithNotes(w, DefaultGMDrumNotes)
+}
+
+// WriteSMFWithNotes converts the pattern to a format 0 Standard MIDI File
+// and writes it to w. Each track's steps become drum notes on channel 10,
+// looked up by name (case-insensitively) in notes and falling back to an
+// acoustic bass drum (36) for names notes doesn't contain, one NoteOn/
+// NoteOff pair per active 16th-note step, preceded by a tempo meta event
+// derived from the pattern's tempo.
+func (p *Pattern) WriteSMFWithNotes(w io.Writer, notes map[string]uint8) error {
+	var track bytes.Buffer
+
+	microsPerQuarter := uint32(60000000 / float64(p.tempo))
+	writeVarLength(&track, 0)
+	track.Write([]byte{0xff, 0x51, 0x03})
+	track.Write([]byte{byte(microsPerQuarter >> 16), byte(microsPerQuarter >> 8), byte(microsPerQuarter)})
+
+	type event struct {
+		tick   uint32
+		noteOn bool
+		note   uint8
+	}
+
+	var events []event
+	for _, tr := range p.tracks {
+		note := noteFor(notes, tr.name)
+		for step, on := range tr.steps {
+			if !on {
+				continue
+			}
+			tick := uint32(step) * (ticksPerQuarterNote / 4)
+			events = append(events, event{tick: tick, noteOn: true, note: note})
+			events = append(events, event{tick: tick + ticksPerQuarterNote/8, noteOn: false, note: note})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].tick < events[j].tick })
+
+	var lastTick uint32
+	for _, ev := range events {
+		writeVarLength(&track, ev.tick-lastTick)
+		lastTick = ev.tick
+
+		status := byte(0x90 | drumChannel)
+		velocity := byte(100)
+		if !ev.noteOn {
+			status = byte(0x80 | drumChannel)
+			velocity = 0
+		}
+		track.Write([]byte{status, ev.note, velocity})
+	}
+
+	writeVarLength(&track, 0)
+	track.Write([]byte{0xff, 0x2f, 0x00})
+
+	header := make([]byte, 14)
+	copy(header, []byte("MThd"))
+	binary.BigEndian.PutUint32(header[4:8], 6)
+	binary.BigEndian.PutUint16(header[8:10], 0)
+	binary.BigEndian.PutUint16(header[10:12], 1)
+	binary.BigEndian.PutUint16(header[12:14], ticksPerQuarterNote)
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+
+	trackHeader := make([]byte, 8)
+	copy(trackHeader, []byte("MTrk"))
+	binary.BigEndian.PutUint32(trackHeader[4:8], uint32(track.Len()))
+
+	if _, err := w.Write(trackHeader); err != nil {
+		return err
+	}
+
+	_, err := w.Write(track.Bytes())
+	return err
+}
+
+// ReadSMF reads a format 0 or 1 Standard MIDI File from r and converts it to
+// a Pattern, naming each track via DefaultGMDrumNotes. See ReadSMFWithNotes
+// to use a different name→note table.
+func ReadSMF(r io.Reader) (*Pattern, error) {
+	return ReadSMFWithNotes(r, DefaultGMDrumNotes)
+}
+
+// ReadSMFWithNotes reads a format 0 or 1 Standard MIDI File from r and
+// converts it to a Pattern, quantizing each NoteOn's onset to the nearest of
+// 16 steps and producing one Track per distinct note number encountered,
+// named by looking the note up in notes (falling back to "Note <n>" for
+// notes it doesn't contain). The division of the source file is honoured;
+// the tempo defaults to 120 BPM unless a tempo meta event is present.
+func ReadSMFWithNotes(r io.Reader, notes map[string]uint8) (*Pattern, error) {
+	header := make([]byte, 14)
+	if _, err := io.ReadFull(r, header); err != nil || string(header[:4]) != "MThd" {
+		return nil, ErrUnsupportedSMF
+	}
+
+	format := binary.BigEndian.Uint16(header[8:10])
+	numTracks := binary.BigEndian.Uint16(header[10:12])
+	division := binary.BigEndian.Uint16(header[12:14])
+	if format > 1 || division&0x8000 != 0 {
+		return nil, ErrUnsupportedSMF
+	}
+	ticksPerStep := float64(division) / 4
+
+	tempo := float32(120)
+	noteSteps := make(map[uint8][16]bool)
+	order := make([]uint8, 0)
+
+	for i := uint16(0); i < numTracks; i++ {
+		chunkHeader := make([]byte, 8)
+		if _, err := io.ReadFull(r, chunkHeader); err != nil || string(chunkHeader[:4]) != "MTrk" {
+			return nil, ErrUnsupportedSMF
+		}
+		length := binary.BigEndian.Uint32(chunkHeader[4:8])
+
+		data := make([]byte, length)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, ErrUnsupportedSMF
+		}
+
+		var tick uint64
+		pos := 0
+		var running byte
+		for pos < len(data) {
+			delta, n := parseVarLength(data[pos:])
+			if n == 0 {
+				return nil, ErrUnsupportedSMF
+			}
+			pos += n
+			tick += uint64(delta)
+
+			if pos >= len(data) {
+				return nil, ErrUnsupportedSMF
+			}
+
+			status := data[pos]
+			if status < 0x80 {
+				status = running
+			} else {
+				pos++
+				running = status
+			}
+
+			switch {
+			case status == 0xff:
+				if pos >= len(data) {
+					return nil, ErrUnsupportedSMF
+				}
+				metaType := data[pos]
+				pos++
+				metaLen, n := parseVarLength(data[pos:])
+				if n == 0 {
+					return nil, ErrUnsupportedSMF
+				}
+				pos += n
+				if pos+int(metaLen) > len(data) {
+					return nil, ErrUnsupportedSMF
+				}
+				if metaType == 0x51 && metaLen == 3 {
+					micros := uint32(data[pos])<<16 | uint32(data[pos+1])<<8 | uint32(data[pos+2])
+					tempo = float32(60000000 / float64(micros))
+				}
+				pos += int(metaLen)
+			case status&0xf0 == 0x90 || status&0xf0 == 0x80:
+				if pos+2 > len(data) {
+					return nil, ErrUnsupportedSMF
+				}
+				note := data[pos]
+				velocity := data[pos+1]
+				pos += 2
+				if status&0xf0 == 0x90 && velocity > 0 {
+					step := int(float64(tick)/ticksPerStep+0.5) % 16
+					if _, seen := noteSteps[note]; !seen {
+						order = append(order, note)
+					}
+					steps := noteSteps[note]
+					steps[step] = true
+					noteSteps[note] = steps
+				}
+			case status == 0xf0 || status == 0xf7:
+				sysexLen, n := parseVarLength(data[pos:])
+				if n == 0 || pos+n+int(sysexLen) > len(data) {
+					return nil, ErrUnsupportedSMF
+				}
+				pos += n + int(sysexLen)
+			default:
+				length := statusDataLength(status)
+				if pos+length > len(data) {
+					return nil, ErrUnsupportedSMF
+				}
+				pos += length
+			}
+		}
+	}
+
+	sort.Slice(order, func(i, j int) bool { return order[i] < order[j] })
+
+	tracks := make([]Track, 0, len(order))
+	for _, note := range order {
+		tracks = append(tracks, Track{
+			index: uint32(len(tracks)),
+			name:  nameFor(notes, note),
+			steps: noteSteps[note],
+		})
+	}
+
+	return &Pattern{version: "SMF import", tempo: tempo, tracks: tracks}, nil
+}
+
+// noteFor returns the drum note assigned to a track name in notes, falling
+// back to an acoustic bass drum (36) for names notes doesn't contain.
+func noteFor(notes map[string]uint8, name string) uint8 {
+	if note, ok := notes[strings.ToLower(name)]; ok {
+		return note
+	}
+	for key, note := range notes {
+		if strings.EqualFold(key, name) {
+			return note
+		}
+	}
+	return 36
+}
+
+// nameFor returns the track name assigned to a drum note in notes, falling
+// back to "Note <n>" for notes it doesn't contain.
+func nameFor(notes map[string]uint8, note uint8) string {
+	for name, n := range notes {
+		if n == note {
+			return name
+		}
+	}
+	return fmt.Sprintf("Note %d", note)
+}
+
+// writeVarLength writes v to w as a MIDI variable-length quantity: 7 bits
+// per byte, big-endian, with the high bit set on every byte but the last.
+func writeVarLength(w io.Writer, v uint32) {
+	buf := []byte{byte(v & 0x7f)}
+	v >>= 7
+	for v > 0 {
+		buf = append([]byte{byte(v&0x7f) | 0x80}, buf...)
+		v >>= 7
+	}
+	w.Write(buf)
+}
+
+// parseVarLength reads a MIDI variable-length quantity from the start of
+// buf, returning its value and the number of bytes consumed, or (0, 0) if
+// buf ends before a terminating byte is found.
+func parseVarLength(buf []byte) (uint32, int) {
+	var value uint32
+	for i, b := range buf {
+		value = value<<7 | uint32(b&0x7f)
+		if b&0x80 == 0 {
+			return value, i + 1
+		}
+	}
+	return 0, 0
+}
+
+// statusDataLength returns the number of data bytes that follow a channel
+// voice message with the given status byte.
+func statusDataLength(status byte) int {
+	switch status & 0xf0 {
+	case 0xc0, 0xd0:
+		return 1
+	default:
+		return 2
+	}
+}
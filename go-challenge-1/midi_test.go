@@ -0,0 +1,140 @@
+package drum
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestWriteReadSMFRoundTrip writes each fixture to a Standard MIDI File and
+// reads it back, checking that every step that was on survives the
+// round trip at the same quantized position.
+func TestWriteReadSMFRoundTrip(t *testing.T) {
+	for i, want := range fixtures {
+		var buf bytes.Buffer
+		if err := want.WriteSMF(&buf); err != nil {
+			t.Fatalf("fixture %d: WriteSMF: %v", i, err)
+		}
+
+		got, err := ReadSMF(&buf)
+		if err != nil {
+			t.Fatalf("fixture %d: ReadSMF: %v", i, err)
+		}
+
+		if len(got.tracks) != len(want.tracks) {
+			t.Fatalf("fixture %d: got %d tracks, want %d", i, len(got.tracks), len(want.tracks))
+		}
+
+		for _, wantTrack := range want.tracks {
+			found := false
+			for _, gotTrack := range got.tracks {
+				if gotTrack.name == wantTrack.name {
+					found = true
+					if gotTrack.steps != wantTrack.steps {
+						t.Errorf("fixture %d: track %q steps = %v, want %v", i, wantTrack.name, gotTrack.steps, wantTrack.steps)
+					}
+					break
+				}
+			}
+			if !found {
+				t.Errorf("fixture %d: track %q missing from round trip", i, wantTrack.name)
+			}
+		}
+	}
+}
+
+// TestWriteSMFWithNotes checks that callers can override the name→note
+// table instead of being stuck with DefaultGMDrumNotes.
+func TestWriteSMFWithNotes(t *testing.T) {
+	p := &Pattern{
+		version: "custom-kit",
+		tempo:   120,
+		tracks: []Track{
+			{index: 0, name: "rimshot", steps: [16]bool{true}},
+		},
+	}
+
+	notes := map[string]uint8{"rimshot": 37}
+
+	var buf bytes.Buffer
+	if err := p.WriteSMFWithNotes(&buf, notes); err != nil {
+		t.Fatalf("WriteSMFWithNotes: %v", err)
+	}
+	written := append([]byte(nil), buf.Bytes()...)
+
+	got, err := ReadSMFWithNotes(bytes.NewReader(written), notes)
+	if err != nil {
+		t.Fatalf("ReadSMFWithNotes: %v", err)
+	}
+
+	if len(got.tracks) != 1 || got.tracks[0].name != "rimshot" {
+		t.Fatalf("ReadSMFWithNotes produced %#v, want a single rimshot track", got.tracks)
+	}
+
+	// Reading the same bytes back with the default table should not
+	// recognise note 37 as "rimshot".
+	defaultRead, err := ReadSMF(bytes.NewReader(written))
+	if err != nil {
+		t.Fatalf("ReadSMF: %v", err)
+	}
+	if len(defaultRead.tracks) != 1 || defaultRead.tracks[0].name == "rimshot" {
+		t.Fatalf("ReadSMF with the default table unexpectedly produced %#v", defaultRead.tracks)
+	}
+}
+
+// TestReadSMFErrors mirrors TestDecodeFileErrors, checking that the bounds
+// checks added to ReadSMF's event parser reject truncated/malformed
+// streams with ErrUnsupportedSMF instead of panicking.
+func TestReadSMFErrors(t *testing.T) {
+	header := func(division uint16) []byte {
+		h := make([]byte, 14)
+		copy(h, "MThd")
+		h[7] = 6
+		h[9] = 1
+		h[10] = 0
+		h[11] = 1
+		h[12] = byte(division >> 8)
+		h[13] = byte(division)
+		return h
+	}
+
+	track := func(data []byte) []byte {
+		tr := make([]byte, 8)
+		copy(tr, "MTrk")
+		tr[4] = byte(len(data) >> 24)
+		tr[5] = byte(len(data) >> 16)
+		tr[6] = byte(len(data) >> 8)
+		tr[7] = byte(len(data))
+		return append(tr, data...)
+	}
+
+	cases := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "not a midi file",
+			data: []byte("NOTMIDI"),
+		},
+		{
+			name: "truncated note-on event",
+			data: append(header(96), track([]byte{0x00, 0x99, 36})...),
+		},
+		{
+			name: "truncated tempo meta event",
+			data: append(header(96), track([]byte{0x00, 0xff, 0x51, 0x03, 0x0f})...),
+		},
+		{
+			name: "truncated sysex event",
+			data: append(header(96), track([]byte{0x00, 0xf0, 0x7f})...),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ReadSMF(bytes.NewReader(tc.data))
+			if err != ErrUnsupportedSMF {
+				t.Fatalf("ReadSMF() error = %v, want %v", err, ErrUnsupportedSMF)
+			}
+		})
+	}
+}
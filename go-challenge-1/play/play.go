@@ -0,0 +1,131 @@
+// Package play renders a drum.Pattern to the system's default audio output.
+// It is kept separate from package drum because it pulls in a hard cgo+ALSA
+// dependency via oto, so that the core decode/encode/midi/edit logic can be
+// built and tested without a system audio library installed.
+package play
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/oto/v2"
+
+	drum "github.com/k0nserv/go-challenge-solutions/go-challenge-1"
+)
+
+const (
+	sampleRate  = 44100
+	stepsPerBar = 16
+)
+
+// voiceFrequencies maps well-known track names to the tone used to
+// synthesize their sample. Names are matched case-insensitively; anything
+// not listed here falls back to a plain click.
+var voiceFrequencies = map[string]float64{
+	"kick":     60,
+	"snare":    200,
+	"hh-open":  8000,
+	"hh-close": 6000,
+	"low tom":  90,
+	"mid tom":  120,
+	"hi tom":   160,
+	"clap":     1500,
+	"cowbell":  560,
+}
+
+// Play renders p to the system's default audio output. Each track's 16
+// steps are scheduled at the pattern's tempo (16th notes, so the step
+// interval is 60/tempo/4 seconds), looping until ctx is canceled or seconds
+// elapses, whichever comes first. A seconds value <= 0 plays forever, until
+// ctx is canceled.
+func Play(ctx context.Context, p *drum.Pattern, seconds int) error {
+	otoCtx, ready, err := oto.NewContext(sampleRate, 1, 2)
+	if err != nil {
+		return err
+	}
+	<-ready
+
+	stepDuration := time.Duration(float64(time.Second) * 60 / float64(p.Tempo()) / 4)
+	if stepDuration <= 0 {
+		stepDuration = time.Second / 4
+	}
+
+	tracks := p.Tracks()
+	voices := make(map[string][]byte, len(tracks))
+	for _, track := range tracks {
+		voices[track.Name()] = voiceFor(track.Name())
+	}
+
+	var deadline time.Time
+	if seconds > 0 {
+		deadline = time.Now().Add(time.Duration(seconds) * time.Second)
+	}
+
+	ticker := time.NewTicker(stepDuration)
+	defer ticker.Stop()
+
+	step := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if !deadline.IsZero() && time.Now().After(deadline) {
+				return nil
+			}
+
+			for _, track := range tracks {
+				steps := track.Steps()
+				if steps[step] {
+					trigger(otoCtx, voices[track.Name()])
+				}
+			}
+			step = (step + 1) % stepsPerBar
+		}
+	}
+}
+
+// trigger plays sample on otoCtx and closes the player once playback
+// finishes, instead of leaking one player per triggered step.
+func trigger(otoCtx *oto.Context, sample []byte) {
+	player := otoCtx.NewPlayer(bytes.NewReader(sample))
+	player.Play()
+
+	go func() {
+		for player.IsPlaying() {
+			time.Sleep(5 * time.Millisecond)
+		}
+		player.Close()
+	}()
+}
+
+// voiceFor returns a short 16-bit little-endian mono PCM sample for the
+// given track name, falling back to a plain synthesized click for names it
+// doesn't recognize.
+func voiceFor(name string) []byte {
+	freq, ok := voiceFrequencies[strings.ToLower(name)]
+	if !ok {
+		freq = 1000
+	}
+	return synthesizeTone(freq, 80*time.Millisecond)
+}
+
+// synthesizeTone renders a decaying sine wave at freq for duration, encoded
+// as 16-bit little-endian PCM samples at sampleRate.
+func synthesizeTone(freq float64, duration time.Duration) []byte {
+	n := int(float64(sampleRate) * duration.Seconds())
+	buf := make([]byte, n*2)
+
+	for i := 0; i < n; i++ {
+		decay := math.Exp(-6 * float64(i) / float64(n))
+		sample := math.Sin(2*math.Pi*freq*float64(i)/sampleRate) * decay
+		v := int16(sample * math.MaxInt16 * 0.8)
+		buf[2*i] = byte(v)
+		buf[2*i+1] = byte(v >> 8)
+	}
+
+	return buf
+}